@@ -0,0 +1,254 @@
+package objloader
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"io"
+	"math"
+)
+
+// Parsing an OBJ is the slow part of using one, so a Scene/MaterialMap pair
+// can be cached once and reloaded straight from bytes -- a JSON form for
+// full fidelity (including materials) and a compact binary form for just
+// the mesh data -- without ever touching the filesystem again.
+
+// sceneJSON is the on-disk shape SaveSceneJSON/LoadSceneJSON use.
+type sceneJSON struct {
+	Objects   map[string]*Object `json:"objects"`
+	Materials MaterialMap        `json:"materials"`
+}
+
+// SaveSceneJSON writes scene and materials to w as JSON.
+func SaveSceneJSON(w io.Writer, scene Scene, materials MaterialMap) error {
+	return json.NewEncoder(w).Encode(sceneJSON{Objects: scene.Objects, Materials: materials})
+}
+
+// LoadSceneJSON reads a Scene/MaterialMap pair previously written by
+// SaveSceneJSON.
+func LoadSceneJSON(r io.Reader) (Scene, MaterialMap, error) {
+	var s sceneJSON
+	if err := json.NewDecoder(r).Decode(&s); err != nil {
+		return Scene{}, nil, err
+	}
+	return Scene{Objects: s.Objects}, s.Materials, nil
+}
+
+const (
+	sceneBinaryMagic   = "OBJB"
+	sceneBinaryVersion = uint32(1)
+)
+
+// SaveSceneBinary writes scene to w in a compact little-endian binary
+// format: a magic/version header, then per object its name and groups, each
+// group as its map key, vtype, material name, smoothing group, stride, raw
+// vertex floats and (if present) indices. Materials are referenced by name
+// only -- pair this with SaveSceneJSON if the full Material data is needed
+// too.
+func SaveSceneBinary(w io.Writer, scene Scene) error {
+	if _, err := io.WriteString(w, sceneBinaryMagic); err != nil {
+		return err
+	}
+	if err := writeUint32(w, sceneBinaryVersion); err != nil {
+		return err
+	}
+	if err := writeUint32(w, uint32(len(scene.Objects))); err != nil {
+		return err
+	}
+	for name, obj := range scene.Objects {
+		if err := writeString(w, name); err != nil {
+			return err
+		}
+		if err := writeUint32(w, uint32(len(obj.Groups))); err != nil {
+			return err
+		}
+		for key, mesh := range obj.Groups {
+			if err := writeString(w, key); err != nil {
+				return err
+			}
+			if err := writeUint32(w, uint32(mesh.Vtype)); err != nil {
+				return err
+			}
+			if err := writeString(w, mesh.MaterialName); err != nil {
+				return err
+			}
+			if err := writeString(w, mesh.Smoothing); err != nil {
+				return err
+			}
+			if err := writeUint32(w, uint32(mesh.Stride)); err != nil {
+				return err
+			}
+			if err := writeFloats(w, mesh.Vertices); err != nil {
+				return err
+			}
+			if err := writeUint32s(w, mesh.Indices); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// LoadSceneBinary reads a Scene previously written by SaveSceneBinary.
+func LoadSceneBinary(r io.Reader) (Scene, error) {
+	magic := make([]byte, len(sceneBinaryMagic))
+	if _, err := io.ReadFull(r, magic); err != nil {
+		return Scene{}, err
+	}
+	if string(magic) != sceneBinaryMagic {
+		return Scene{}, errorInvalidFormat("Not a scene binary file", string(magic))
+	}
+	version, err := readUint32(r)
+	if err != nil {
+		return Scene{}, err
+	}
+	if version != sceneBinaryVersion {
+		return Scene{}, errorNotSupported("Unsupported scene binary version", version)
+	}
+	objectCount, err := readUint32(r)
+	if err != nil {
+		return Scene{}, err
+	}
+	scene := Scene{Objects: make(map[string]*Object, objectCount)}
+	for i := uint32(0); i < objectCount; i++ {
+		name, err := readString(r)
+		if err != nil {
+			return Scene{}, err
+		}
+		groupCount, err := readUint32(r)
+		if err != nil {
+			return Scene{}, err
+		}
+		obj := &Object{Name: name, Groups: make(map[string]*Mesh, groupCount)}
+		for j := uint32(0); j < groupCount; j++ {
+			key, err := readString(r)
+			if err != nil {
+				return Scene{}, err
+			}
+			vtype, err := readUint32(r)
+			if err != nil {
+				return Scene{}, err
+			}
+			materialName, err := readString(r)
+			if err != nil {
+				return Scene{}, err
+			}
+			smoothing, err := readString(r)
+			if err != nil {
+				return Scene{}, err
+			}
+			stride, err := readUint32(r)
+			if err != nil {
+				return Scene{}, err
+			}
+			vertices, err := readFloats(r)
+			if err != nil {
+				return Scene{}, err
+			}
+			indices, err := readUint32s(r)
+			if err != nil {
+				return Scene{}, err
+			}
+			obj.Groups[key] = &Mesh{
+				Vertices:     vertices,
+				Vtype:        int(vtype),
+				MaterialName: materialName,
+				Smoothing:    smoothing,
+				Stride:       int(stride),
+				Indices:      indices,
+			}
+		}
+		scene.Objects[name] = obj
+	}
+	return scene, nil
+}
+
+func writeUint32(w io.Writer, v uint32) error {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], v)
+	_, err := w.Write(buf[:])
+	return err
+}
+
+func readUint32(r io.Reader) (uint32, error) {
+	var buf [4]byte
+	if _, err := io.ReadFull(r, buf[:]); err != nil {
+		return 0, err
+	}
+	return binary.LittleEndian.Uint32(buf[:]), nil
+}
+
+func writeString(w io.Writer, s string) error {
+	if err := writeUint32(w, uint32(len(s))); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, s)
+	return err
+}
+
+func readString(r io.Reader) (string, error) {
+	n, err := readUint32(r)
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, n)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+func writeFloats(w io.Writer, vals []float32) error {
+	if err := writeUint32(w, uint32(len(vals))); err != nil {
+		return err
+	}
+	buf := make([]byte, len(vals)*4)
+	for i, v := range vals {
+		binary.LittleEndian.PutUint32(buf[i*4:], math.Float32bits(v))
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func readFloats(r io.Reader) ([]float32, error) {
+	n, err := readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, int(n)*4)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	vals := make([]float32, n)
+	for i := range vals {
+		vals[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[i*4:]))
+	}
+	return vals, nil
+}
+
+func writeUint32s(w io.Writer, vals []uint32) error {
+	if err := writeUint32(w, uint32(len(vals))); err != nil {
+		return err
+	}
+	buf := make([]byte, len(vals)*4)
+	for i, v := range vals {
+		binary.LittleEndian.PutUint32(buf[i*4:], v)
+	}
+	_, err := w.Write(buf)
+	return err
+}
+
+func readUint32s(r io.Reader) ([]uint32, error) {
+	n, err := readUint32(r)
+	if err != nil {
+		return nil, err
+	}
+	buf := make([]byte, int(n)*4)
+	if _, err := io.ReadFull(r, buf); err != nil {
+		return nil, err
+	}
+	vals := make([]uint32, n)
+	for i := range vals {
+		vals[i] = binary.LittleEndian.Uint32(buf[i*4:])
+	}
+	return vals, nil
+}
@@ -0,0 +1,85 @@
+package objloader
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSaveLoadSceneBinaryRoundTrip(t *testing.T) {
+	scene := Scene{
+		Objects: map[string]*Object{
+			"cube": {
+				Name: "cube",
+				Groups: map[string]*Mesh{
+					"group1-vtype2-materialred": {
+						Vertices:     []float32{0, 0, 0, 0, 1, 1, 0, 0, 1, 1, 1, 0, 2, 2, 2},
+						Vtype:        2,
+						MaterialName: "red",
+						Smoothing:    "1",
+						Stride:       5,
+						Indices:      []uint32{0, 1, 2, 2, 1, 0},
+					},
+				},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	if err := SaveSceneBinary(&buf, scene); err != nil {
+		t.Fatalf("SaveSceneBinary: %v", err)
+	}
+
+	got, err := LoadSceneBinary(&buf)
+	if err != nil {
+		t.Fatalf("LoadSceneBinary: %v", err)
+	}
+
+	obj := got.Objects["cube"]
+	if obj == nil {
+		t.Fatal("missing object \"cube\" after round trip")
+	}
+	if obj.Name != "cube" {
+		t.Fatalf("object name = %q, want %q", obj.Name, "cube")
+	}
+	mesh := obj.Groups["group1-vtype2-materialred"]
+	if mesh == nil {
+		t.Fatal("missing group after round trip")
+	}
+
+	want := scene.Objects["cube"].Groups["group1-vtype2-materialred"]
+	if mesh.Vtype != want.Vtype {
+		t.Errorf("Vtype = %d, want %d", mesh.Vtype, want.Vtype)
+	}
+	if mesh.MaterialName != want.MaterialName {
+		t.Errorf("MaterialName = %q, want %q", mesh.MaterialName, want.MaterialName)
+	}
+	if mesh.Smoothing != want.Smoothing {
+		t.Errorf("Smoothing = %q, want %q", mesh.Smoothing, want.Smoothing)
+	}
+	if mesh.Stride != want.Stride {
+		t.Errorf("Stride = %d, want %d", mesh.Stride, want.Stride)
+	}
+	if len(mesh.Vertices) != len(want.Vertices) {
+		t.Fatalf("Vertices = %v, want %v", mesh.Vertices, want.Vertices)
+	}
+	for i := range want.Vertices {
+		if mesh.Vertices[i] != want.Vertices[i] {
+			t.Fatalf("Vertices[%d] = %v, want %v", i, mesh.Vertices[i], want.Vertices[i])
+		}
+	}
+	if len(mesh.Indices) != len(want.Indices) {
+		t.Fatalf("Indices = %v, want %v", mesh.Indices, want.Indices)
+	}
+	for i := range want.Indices {
+		if mesh.Indices[i] != want.Indices[i] {
+			t.Fatalf("Indices[%d] = %v, want %v", i, mesh.Indices[i], want.Indices[i])
+		}
+	}
+}
+
+func TestLoadSceneBinaryRejectsBadMagic(t *testing.T) {
+	_, err := LoadSceneBinary(bytes.NewReader([]byte("NOPE")))
+	if err == nil {
+		t.Fatal("expected an error for an invalid magic header")
+	}
+}
@@ -3,32 +3,124 @@ package objloader
 import (
 	"bufio"
 	"fmt"
+	"io"
+	"math"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
 
-func sliceToFloat32(parts []string) ([]float32, error) {
-	result := []float32{}
-	for _, v := range parts {
-		num, err := strconv.ParseFloat(v, 32)
-		if err != nil {
-			return result, err
+// Reading OBJ/MTL files line-by-line with strconv.ParseFloat/fmt.Fscanf is
+// the hot path for large models, and both allocate a substring per field.
+// parseFloatField and parseFace below are a small hand-rolled tokenizer that
+// walks the raw line bytes directly instead.
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func skipSpace(b []byte) int {
+	i := 0
+	for i < len(b) && isSpace(b[i]) {
+		i++
+	}
+	return i
+}
+
+// splitKey splits a raw OBJ/MTL line into its leading keyword and the
+// remaining bytes, e.g. "v 1.0 2.0 3.0" -> ("v", " 1.0 2.0 3.0").
+func splitKey(line []byte) (key, rest []byte) {
+	i := skipSpace(line)
+	start := i
+	for i < len(line) && !isSpace(line[i]) {
+		i++
+	}
+	return line[start:i], line[i:]
+}
+
+// parseFloatField parses one float32 token from the start of b, skipping any
+// leading whitespace, and returns the value along with the number of bytes
+// consumed so the caller can keep slicing through the rest of the line. n is
+// 0 when b holds no more tokens. It understands an optional sign, integer
+// and decimal digits, and an optional e[+-]?digits exponent -- the subset of
+// the %g grammar OBJ vertex data uses.
+func parseFloatField(b []byte) (float32, int, error) {
+	i := skipSpace(b)
+	if i == len(b) {
+		return 0, 0, nil
+	}
+	start := i
+	if b[i] == '+' || b[i] == '-' {
+		i++
+	}
+	var mantissa float64
+	digits := 0
+	for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+		mantissa = mantissa*10 + float64(b[i]-'0')
+		digits++
+		i++
+	}
+	if i < len(b) && b[i] == '.' {
+		i++
+		frac := 0.1
+		for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+			mantissa += float64(b[i]-'0') * frac
+			frac /= 10
+			digits++
+			i++
+		}
+	}
+	if digits == 0 {
+		return 0, i, errorInvalidFormat("Expected a number", string(b[start:i]))
+	}
+	if b[start] == '-' {
+		mantissa = -mantissa
+	}
+	if i < len(b) && (b[i] == 'e' || b[i] == 'E') {
+		j := i + 1
+		expNeg := false
+		if j < len(b) && (b[j] == '+' || b[j] == '-') {
+			expNeg = b[j] == '-'
+			j++
+		}
+		expStart := j
+		exp := 0
+		for j < len(b) && b[j] >= '0' && b[j] <= '9' {
+			exp = exp*10 + int(b[j]-'0')
+			j++
+		}
+		if j > expStart {
+			if expNeg {
+				exp = -exp
+			}
+			mantissa *= math.Pow(10, float64(exp))
+			i = j
 		}
-		result = append(result, float32(num))
 	}
-	return result, nil
+	return float32(mantissa), i, nil
 }
-func sliceToInt(parts []string) ([]int, error) {
-	result := []int{}
-	for _, v := range parts {
-		num, err := strconv.Atoi(v)
+
+// parseFloatFields parses exactly want floats out of b using parseFloatField,
+// returning an error if there are too few, too many, or any fail to parse.
+func parseFloatFields(b []byte, want int) ([]float32, error) {
+	vals := make([]float32, 0, want)
+	rest := b
+	for {
+		val, n, err := parseFloatField(rest)
 		if err != nil {
-			return result, err
+			return nil, err
+		}
+		if n == 0 {
+			break
 		}
-		result = append(result, num)
+		vals = append(vals, val)
+		rest = rest[n:]
 	}
-	return result, nil
+	if len(vals) != want {
+		return nil, errorInvalidFormat(fmt.Sprintf("must have %d element(s)", want), string(b))
+	}
+	return vals, nil
 }
 
 /*
@@ -36,51 +128,92 @@ IT TRIES TO FOLLOW THE FOLLOWING SPECS
 https://www.martinreddy.net/gfx/3d/OBJ.spec
 */
 
-func getFaceType(p string) int {
-	v := strings.Split(p, "/")
-	if len(v) == 0 {
-		return -1
-	} // invalid type
-	if len(v) == 1 {
-		return 0
-	} // f v
-	if len(v) == 2 {
-		return 1
-	} // f v/vt
-	if len(v) == 3 {
-		if v[1] != "" {
-			return 2 // f v/vt/vn
-		} else {
-			return 3 // f v//vn
+// parseFace parses one OBJ face vertex reference (e.g. "3", "3/4", "3/4/5"
+// or "3//5") from the start of b, skipping any leading whitespace. It
+// returns the v/vt/vn indices (0 where a field is absent -- real OBJ indices
+// are never 0) and the number of bytes consumed; n is 0 when b holds no more
+// references.
+func parseFace(b []byte) ([3]int, int, error) {
+	var out [3]int
+	i := skipSpace(b)
+	if i == len(b) {
+		return out, 0, nil
+	}
+	start := i
+	comp := 0
+	for i < len(b) && !isSpace(b[i]) {
+		if b[i] == '/' {
+			comp++
+			if comp > 2 {
+				return out, i, errorInvalidFormat("Face reference has too many '/' separated fields", string(b[start:]))
+			}
+			i++
+			continue
+		}
+		neg := b[i] == '-'
+		if neg {
+			i++
 		}
+		digitStart := i
+		val := 0
+		for i < len(b) && b[i] >= '0' && b[i] <= '9' {
+			val = val*10 + int(b[i]-'0')
+			i++
+		}
+		if i == digitStart {
+			return out, i, errorInvalidFormat("Expected a face index", string(b[start:]))
+		}
+		if neg {
+			val = -val
+		}
+		out[comp] = val
 	}
-	return -1
+	return out, i, nil
 }
-func getFaceData(p string, vtype int) []int {
-	result := []int{}
-	reader := strings.NewReader(p)
-	switch vtype {
-	case 0: // f v
-		var a int
-		fmt.Fscanf(reader, "%d", &a)
-		result = append(result, a)
-	case 1: // f v/vt
-		var a, b int
-		fmt.Fscanf(reader, "%d/%d", &a, &b)
-		result = append(result, []int{a, b}...)
-	case 2: // f v/vt/vn
-		var a, b, c int
-		fmt.Fscanf(reader, "%d/%d/%d", &a, &b, &c)
-		result = append(result, []int{a, b, c}...)
-	case 3: // f v//vn
-		var a, b int
-		fmt.Fscanf(reader, "%d//%d", &a, &b)
-		result = append(result, []int{a, b}...)
+
+// faceVtype reports which OBJ face format (matching the old v/v-vt/v-vt-vn/
+// v--vn encoding: 0/1/2/3) a parsed face reference used, based on which of
+// its vt/vn fields are present.
+func faceVtype(face [3]int) int {
+	hasVT := face[1] != 0
+	hasVN := face[2] != 0
+	switch {
+	case hasVT && hasVN:
+		return 2 // f v/vt/vn
+	case hasVT:
+		return 1 // f v/vt
+	case hasVN:
+		return 3 // f v//vn
 	default:
-		fmt.Println(errorInvalidFormat("Invalid Vtype", vtype))
+		return 0 // f v
+	}
+}
+
+// resolveFaceIndices rewrites any negative OBJ indices in faceData (relative
+// to the end of their respective positions/vt/vn arrays) into the equivalent
+// positive, 1-based ones, in place.
+func resolveFaceIndices(faceData []int, vtype, nPos, nUV, nNorm int) {
+	faceData[0] = resolveIndex(faceData[0], nPos)
+	switch vtype {
+	case 1: // v/vt
+		faceData[1] = resolveIndex(faceData[1], nUV)
+	case 2: // v/vt/vn
+		faceData[1] = resolveIndex(faceData[1], nUV)
+		faceData[2] = resolveIndex(faceData[2], nNorm)
+	case 3: // v//vn
+		faceData[2] = resolveIndex(faceData[2], nNorm)
 	}
-	return result
 }
+
+// resolveIndex turns a negative index -k into len-k+1 so it points at the
+// same element a plain 1-based index would, k elements from the end.
+func resolveIndex(idx, length int) int {
+	if idx < 0 {
+		return length + idx + 1
+	}
+	return idx
+}
+
 func errorInvalidFormat(msg string, p any) error {
 	return fmt.Errorf("INVALID FORMAT: %s _> %v", msg, p)
 }
@@ -95,10 +228,52 @@ type Mesh struct {
 	Vertices     []float32
 	Vtype        int
 	MaterialName string
+	// Smoothing holds the smoothing group ("off" or a group number) in
+	// effect when the mesh's first face was read; later `s` statements do
+	// not change it.
+	Smoothing string
+	// Stride is the number of float32s per vertex in Vertices (position,
+	// plus uv and/or normal when the mesh's vtype/Options carry them).
+	Stride int
+	// Indices indexes into Vertices (stride-sized records) instead of
+	// repeating them; only populated when Options.Indexed is set.
+	Indices []uint32
+}
+
+// appendVertex appends one stride-sized vertex record (position, optional
+// uv, optional normal) to dst and returns the grown slice.
+func appendVertex(dst []float32, pos Vec3, uv Vec2, hasUV bool, normal Vec3, hasNormal bool) []float32 {
+	dst = append(dst, pos[:]...)
+	if hasUV {
+		dst = append(dst, uv[:]...)
+	}
+	if hasNormal {
+		dst = append(dst, normal[:]...)
+	}
+	return dst
+}
+
+// Object mirrors an OBJ `o` statement: everything read until the next `o`
+// belongs to it, further split into Groups by the `g` statements (and
+// material/vtype) in effect for each face.
+type Object struct {
+	Name   string
+	Groups MeshMap
+}
+
+// Scene is the result of LoadObj: the file's objects, each holding the
+// groups of faces defined within it. This replaces one flat vertex buffer
+// with the object/group structure tools like tobj expose, so callers can
+// render or select sub-objects instead of the whole file at once.
+type Scene struct {
+	Objects map[string]*Object
 }
 
 type Options struct {
 	NeedNormals bool
+	// Indexed requests a deduplicated, indexed vertex buffer per mesh
+	// (Mesh.Vertices + Mesh.Indices) instead of an expanded triangle soup.
+	Indexed bool
 }
 
 type Material struct {
@@ -112,114 +287,186 @@ type Material struct {
 	Ks    Vec3
 	Ke    Vec3
 	Ki    Vec3
+
+	// Texture maps. Empty string means the map wasn't set.
+	MapKa   string
+	MapKd   string
+	MapKs   string
+	MapNs   string
+	MapD    string
+	MapBump string
+	Disp    string
+	Decal   string
+	Norm    string
+
+	// MapOptions holds the -o/-s/-bm modifiers for each texture map
+	// statement above, keyed by its keyword (e.g. "map_Kd", "map_Bump").
+	MapOptions map[string]TextureMapOptions
+}
+
+// TextureMapOptions holds the positional modifiers that can precede a
+// texture map's filename, e.g. "map_Kd -o 0 0 0 -s 1 1 1 diffuse.png".
+type TextureMapOptions struct {
+	Offset   Vec3
+	Scale    Vec3
+	BumpMult float32
+}
+
+// parseTextureMap parses a texture-map statement's arguments -- any of the
+// -o/-s/-bm modifiers followed by the image filename -- and returns the
+// filename and the modifiers found.
+func parseTextureMap(rest []byte) (string, TextureMapOptions, error) {
+	opts := TextureMapOptions{Scale: Vec3{1, 1, 1}}
+	tokens := strings.Fields(string(rest))
+	file := ""
+	for i := 0; i < len(tokens); i++ {
+		switch tokens[i] {
+		case "-o", "-s":
+			if i+3 >= len(tokens) {
+				return "", opts, errorInvalidFormat(tokens[i]+" needs 3 values", tokens)
+			}
+			vals, err := parseFloatFields([]byte(strings.Join(tokens[i+1:i+4], " ")), 3)
+			if err != nil {
+				return "", opts, err
+			}
+			v := Vec3{vals[0], vals[1], vals[2]}
+			if tokens[i] == "-o" {
+				opts.Offset = v
+			} else {
+				opts.Scale = v
+			}
+			i += 3
+		case "-bm":
+			if i+1 >= len(tokens) {
+				return "", opts, errorInvalidFormat(tokens[i]+" needs 1 value", tokens)
+			}
+			vals, err := parseFloatFields([]byte(tokens[i+1]), 1)
+			if err != nil {
+				return "", opts, err
+			}
+			opts.BumpMult = vals[0]
+			i++
+		default:
+			file = tokens[i]
+		}
+	}
+	if file == "" {
+		return "", opts, errorInvalidFormat("Texture map statement missing filename", string(rest))
+	}
+	return file, opts, nil
 }
 
 // It assumes mtl file have absolute path or path relative to current dir
 func LoadMtl(filePath string, materials *MaterialMap) error {
-	var m *Material
 	f, err := os.Open(filePath)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-	scanner := bufio.NewScanner(f)
+	return LoadMtlReader(f, materials)
+}
+
+// LoadMtlReader is the io.Reader-based core LoadMtl wraps, for callers that
+// already have their material data open (embed.FS, zip archives, HTTP, ...).
+func LoadMtlReader(r io.Reader, materials *MaterialMap) error {
+	var m *Material
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
 	for scanner.Scan() {
-		rawText := scanner.Text()
-		parts := strings.Fields(rawText)
-		if len(parts) == 0 {
+		key, rest := splitKey(scanner.Bytes())
+		if len(key) == 0 {
 			continue
 		}
-		key := parts[0]
-		parts = parts[1:]
-		switch key {
+		switch string(key) {
 		case "newmtl":
-			m = &Material{Name: parts[0]}
-			(*materials)[parts[0]] = m
+			name := strings.TrimSpace(string(rest))
+			m = &Material{Name: name, MapOptions: map[string]TextureMapOptions{}}
+			(*materials)[name] = m
 		case "Ns":
-			if len(parts) < 1 {
-				return errorInvalidFormat("'Ns' must have 1 element", parts)
-			}
-			num, err := strconv.ParseFloat(parts[0], 32)
+			nums, err := parseFloatFields(rest, 1)
 			if err != nil {
 				return err
 			}
-			m.Ns = float32(num)
+			m.Ns = nums[0]
 		case "Ni":
-			if len(parts) < 1 {
-				return errorInvalidFormat("'Ni' must have 1 element", parts)
-			}
-			num, err := strconv.ParseFloat(parts[0], 32)
+			nums, err := parseFloatFields(rest, 1)
 			if err != nil {
 				return err
 			}
-			m.Ni = float32(num)
+			m.Ni = nums[0]
 		case "d":
-			if len(parts) < 1 {
-				return errorInvalidFormat("'d' must have 1 element", parts)
-			}
-			num, err := strconv.ParseFloat(parts[0], 32)
+			nums, err := parseFloatFields(rest, 1)
 			if err != nil {
 				return err
 			}
-			m.D = float32(num)
+			m.D = nums[0]
 		case "illum":
-			if len(parts) < 1 {
-				return errorInvalidFormat("'illum' must have 1 element", parts)
-			}
-			num, err := strconv.Atoi(parts[0])
+			num, err := strconv.Atoi(strings.TrimSpace(string(rest)))
 			if err != nil {
 				return err
 			}
 			m.Illum = int32(num)
 		case "Ka":
-			if len(parts) < 3 {
-				return errorInvalidFormat("'Ka' must have 3 element", parts)
-			}
-			nums, err := sliceToFloat32(parts)
+			nums, err := parseFloatFields(rest, 3)
 			if err != nil {
 				return err
 			}
 			m.Ka = Vec3{nums[0], nums[1], nums[2]}
 		case "Kd":
-			if len(parts) < 3 {
-				return errorInvalidFormat("'Kd' must have 3 element", parts)
-			}
-			nums, err := sliceToFloat32(parts)
+			nums, err := parseFloatFields(rest, 3)
 			if err != nil {
 				return err
 			}
 			m.Kd = Vec3{nums[0], nums[1], nums[2]}
 
 		case "Ke":
-			if len(parts) < 3 {
-				return errorInvalidFormat("'Ke' must have 3 element", parts)
-			}
-			nums, err := sliceToFloat32(parts)
+			nums, err := parseFloatFields(rest, 3)
 			if err != nil {
 				return err
 			}
 			m.Ke = Vec3{nums[0], nums[1], nums[2]}
 
 		case "Ki":
-			if len(parts) < 3 {
-				return errorInvalidFormat("'Ki' must have 3 element", parts)
-			}
-			nums, err := sliceToFloat32(parts)
+			nums, err := parseFloatFields(rest, 3)
 			if err != nil {
 				return err
 			}
 			m.Ki = Vec3{nums[0], nums[1], nums[2]}
 
 		case "Ks":
-			if len(parts) < 3 {
-				return errorInvalidFormat("'Ks' must have 3 element", parts)
-			}
-			nums, err := sliceToFloat32(parts)
+			nums, err := parseFloatFields(rest, 3)
 			if err != nil {
 				return err
 			}
 			m.Ks = Vec3{nums[0], nums[1], nums[2]}
 
+		case "map_Ka", "map_Kd", "map_Ks", "map_Ns", "map_d", "map_Bump", "bump", "disp", "decal", "norm":
+			file, opts, err := parseTextureMap(rest)
+			if err != nil {
+				return err
+			}
+			m.MapOptions[string(key)] = opts
+			switch string(key) {
+			case "map_Ka":
+				m.MapKa = file
+			case "map_Kd":
+				m.MapKd = file
+			case "map_Ks":
+				m.MapKs = file
+			case "map_Ns":
+				m.MapNs = file
+			case "map_d":
+				m.MapD = file
+			case "map_Bump", "bump":
+				m.MapBump = file
+			case "disp":
+				m.Disp = file
+			case "decal":
+				m.Decal = file
+			case "norm":
+				m.Norm = file
+			}
+
 		}
 	}
 	return nil
@@ -228,14 +475,46 @@ func LoadMtl(filePath string, materials *MaterialMap) error {
 type MaterialMap = map[string]*Material
 type MeshMap = map[string]*Mesh
 
-func LoadObj(filePath string, meshes *MeshMap, materials *MaterialMap, options Options) error {
+func LoadObj(filePath string, scene *Scene, materials *MaterialMap, options Options) error {
 
 	f, err := os.Open(filePath)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
-	scanner := bufio.NewScanner(f)
+	return LoadObjReader(f, fileResolver{baseDir: filepath.Dir(filePath)}, scene, materials, options)
+}
+
+// Resolver resolves a name referenced from an OBJ file (currently just
+// mtllib entries) to a readable source, so LoadObjReader can work from
+// embed.FS, zip archives, or HTTP without touching the filesystem directly.
+type Resolver interface {
+	Open(name string) (io.ReadCloser, error)
+}
+
+// fileResolver is the Resolver LoadObj uses: it resolves names relative to
+// the OBJ file's own directory (absolute names are opened as they are).
+type fileResolver struct {
+	baseDir string
+}
+
+func (r fileResolver) Open(name string) (io.ReadCloser, error) {
+	path := name
+	if !filepath.IsAbs(path) {
+		path = filepath.Join(r.baseDir, path)
+	}
+	return os.Open(path)
+}
+
+// LoadObjReader is the io.Reader-based core LoadObj wraps. mtllib entries
+// are resolved through resolver instead of the filesystem.
+func LoadObjReader(r io.Reader, resolver Resolver, scene *Scene, materials *MaterialMap, options Options) error {
+	if scene.Objects == nil {
+		scene.Objects = map[string]*Object{}
+	}
+
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
 
 	positions := []Vec3{}
 	normals := []Vec3{}
@@ -245,27 +524,27 @@ func LoadObj(filePath string, meshes *MeshMap, materials *MaterialMap, options O
 	facesCounter := 0
 
 	currentMaterial := "default"
+	currentObject := "default"
+	currentGroup := "default"
+	currentSmoothing := "off"
 	_nsMap := map[string]bool{}
+	// vertexIndex holds the (posIdx, uvIdx, nIdx) -> Vertices-index dedup
+	// map for each mesh, only used when options.Indexed is set.
+	vertexIndex := map[*Mesh]map[[3]int]uint32{}
 
 	for scanner.Scan() {
 		lineCounter++
-		rawText := scanner.Text()
-		parts := strings.Fields(rawText)
-		if len(parts) == 0 {
+		key, rest := splitKey(scanner.Bytes())
+		if len(key) == 0 {
 			continue
 		}
-		key := parts[0]
-		parts = parts[1:]
-		switch key {
+		switch string(key) {
 		case "#": // USED FOR COMMENTS
 
 		// VERTEX DATA _> (v) (vn) (vt) (vp) (cstype) (deg) (bmat) (step)
 		// VERTEX POSITIONS [x,y,z,(w)] Required
 		case "v":
-			if len(parts) > 3 {
-				return errorInvalidFormat("Vertex Position can only have 3 elements", parts)
-			}
-			vert, err := sliceToFloat32(parts[:3])
+			vert, err := parseFloatFields(rest, 3)
 			if err != nil {
 				return err
 			}
@@ -273,10 +552,7 @@ func LoadObj(filePath string, meshes *MeshMap, materials *MaterialMap, options O
 
 		// SURFACE NORMALS [i,j,k] Optional / Can be recalculated using (v)
 		case "vn":
-			if len(parts) > 3 {
-				return errorInvalidFormat("Surface Normal can only have 3 elements", parts)
-			}
-			norm, err := sliceToFloat32(parts[:3])
+			norm, err := parseFloatFields(rest, 3)
 			if err != nil {
 				return err
 			}
@@ -284,10 +560,7 @@ func LoadObj(filePath string, meshes *MeshMap, materials *MaterialMap, options O
 
 		// TEXTURE COORDINATES [u,v] Optional
 		case "vt":
-			if len(parts) > 2 {
-				return errorInvalidFormat("Texture coords only have 2 elements", parts)
-			}
-			uv, err := sliceToFloat32(parts[:2])
+			uv, err := parseFloatFields(rest, 2)
 			if err != nil {
 				return err
 			}
@@ -297,149 +570,201 @@ func LoadObj(filePath string, meshes *MeshMap, materials *MaterialMap, options O
 		// FACES
 		case "f":
 
-			// TODO: SUPPORT NEGATIVE INDICES
-			// TODO: SUPPORT MORE THAN 3 FACES (TRIANGLE_FAN)
-
-			// ERROR CHECKING
-			vtype := getFaceType(strings.Trim(parts[0], "/"))
-			if len(parts) < 3 {
-				return errorInvalidFormat("Faces cannot have length less than 3", parts)
-			}
-			if len(parts) != 3 {
-				return errorNotSupported("Only Supports faces with length 3", parts)
+			faces := [][3]int{}
+			for {
+				face, n, err := parseFace(rest)
+				if n == 0 {
+					break
+				}
+				if err != nil {
+					return err
+				}
+				faces = append(faces, face)
+				rest = rest[n:]
 			}
-			if vtype == -1 {
-				return errorInvalidFormat("Undefined face format", parts)
+			if len(faces) < 3 {
+				return errorInvalidFormat("Faces cannot have length less than 3", faces)
 			}
 
-			faces := [][]int{}
-			for _, p := range parts {
-				p = strings.Trim(p, "/") // Trim leading and trailing '/'
-				if vtype != getFaceType(p) {
-					return errorInvalidFormat("Faces cannot have inconsistent format", parts)
+			// ERROR CHECKING
+			vtype := faceVtype(faces[0])
+			for _, face := range faces {
+				if faceVtype(face) != vtype {
+					return errorInvalidFormat("Faces cannot have inconsistent format", faces)
 				}
-				faceData := getFaceData(p, vtype)
-				faces = append(faces, faceData)
 			}
-
-			if len(faces)%3 != 0 {
-				return errorInvalidFormat("Undefined Behavior", parts)
+			for i := range faces {
+				resolveFaceIndices(faces[i][:], vtype, len(positions), len(uvs), len(normals))
 			}
 
-			vertices := []float32{}
+			// parseFace always lays a face reference out as [v, vt, vn], so
+			// vt (when present) is at index 1 and vn (when present) is at
+			// index 2 regardless of which of the two is missing.
 			uvIdx := -1
 			nIdx := -1
-			if vtype == 2 {
+			if vtype == 2 || vtype == 3 {
 				nIdx = 2
-			} else if vtype == 3 {
-				nIdx = 1
 			}
 			if vtype == 2 || vtype == 1 {
 				uvIdx = 1
 			}
 
-			for i := 0; i < len(faces); i += 3 {
-				f1 := faces[i]
-				f2 := faces[i+1]
-				f3 := faces[i+2]
-				// POSITIONS
-				va := positions[f1[0]-1]
-				vb := positions[f2[0]-1]
-				vc := positions[f3[0]-1]
-				// NORMALS
-				var na, nb, nc Vec3
+			stride := 3
+			if uvIdx != -1 {
+				stride += 2
+			}
+			if options.NeedNormals {
+				stride += 3
+			}
+
+			obj, ok := scene.Objects[currentObject]
+			if !ok {
+				obj = &Object{Name: currentObject, Groups: map[string]*Mesh{}}
+				scene.Objects[currentObject] = obj
+			}
+			meshID := fmt.Sprintf("group%s-vtype%d-material%s", currentGroup, vtype, currentMaterial)
+			mesh, ok := obj.Groups[meshID]
+			if !ok {
+				mesh = &Mesh{Vertices: []float32{}, Vtype: vtype, MaterialName: currentMaterial, Smoothing: currentSmoothing, Stride: stride}
+				obj.Groups[meshID] = mesh
+			}
+			// Flat (per-face) normals are unique to each face, so a vertex
+			// that needs one can never be shared across faces.
+			dedupable := options.Indexed && (nIdx != -1 || !options.NeedNormals)
+			var dedup map[[3]int]uint32
+			if dedupable {
+				dedup = vertexIndex[mesh]
+				if dedup == nil {
+					dedup = map[[3]int]uint32{}
+					vertexIndex[mesh] = dedup
+				}
+			}
+
+			// FAN TRIANGULATION: (v0,vi,vi+1) for i=1..N-2, supports any N>=3
+			for i := 1; i < len(faces)-1; i++ {
+				tri := [3][3]int{faces[0], faces[i], faces[i+1]}
+
+				var triPos, triNormal [3]Vec3
+				var triUV [3]Vec2
+				for k, f := range tri {
+					triPos[k] = positions[f[0]-1]
+				}
 				if nIdx != -1 {
-					na = normals[f1[nIdx]-1]
-					nb = normals[f2[nIdx]-1]
-					nc = normals[f3[nIdx]-1]
+					for k, f := range tri {
+						triNormal[k] = normals[f[nIdx]-1]
+					}
 				} else if options.NeedNormals {
-					u := Vec3{vb[0] - va[0], vb[1] - va[1], vb[2] - va[2]}
-					v := Vec3{vc[0] - va[0], vc[1] - va[1], vc[2] - va[2]}
+					u := Vec3{triPos[1][0] - triPos[0][0], triPos[1][1] - triPos[0][1], triPos[1][2] - triPos[0][2]}
+					v := Vec3{triPos[2][0] - triPos[0][0], triPos[2][1] - triPos[0][1], triPos[2][2] - triPos[0][2]}
 					n := Vec3{
 						u[1]*v[2] - u[2]*v[1],
 						u[2]*v[0] - u[0]*v[2],
 						u[0]*v[1] - u[1]*v[0],
 					}
-					na = n
-					nb = n
-					nc = n
+					triNormal = [3]Vec3{n, n, n}
 				}
-				// UVS
-				var uva, uvb, uvc Vec2
 				if uvIdx != -1 {
-					uva = uvs[f1[uvIdx]-1]
-					uvb = uvs[f2[uvIdx]-1]
-					uvc = uvs[f3[uvIdx]-1]
+					for k, f := range tri {
+						triUV[k] = uvs[f[uvIdx]-1]
+					}
 				}
 
-				// v1
-				vertices = append(vertices, va[:]...)
-				if uvIdx != -1 {
-					vertices = append(vertices, uva[:]...)
-				}
-				if options.NeedNormals {
-					vertices = append(vertices, na[:]...)
-				}
-				// v2
-				vertices = append(vertices, vb[:]...)
-				if uvIdx != -1 {
-					vertices = append(vertices, uvb[:]...)
-				}
-				if options.NeedNormals {
-					vertices = append(vertices, nb[:]...)
-				}
-				// v3
-				vertices = append(vertices, vc[:]...)
-				if uvIdx != -1 {
-					vertices = append(vertices, uvc[:]...)
-				}
-				if options.NeedNormals {
-					vertices = append(vertices, nc[:]...)
-				}
-			}
-			if len(vertices) != 0 {
-				meshID := fmt.Sprintf("mesh-vtype%d-material%s", vtype, currentMaterial)
-				_, ok := (*meshes)[meshID]
-				if !ok {
-					(*meshes)[meshID] = &Mesh{Vertices: []float32{}, Vtype: vtype, MaterialName: currentMaterial}
+				for k := 0; k < 3; k++ {
+					if options.Indexed && dedupable {
+						key := [3]int{tri[k][0], 0, 0}
+						if uvIdx != -1 {
+							key[1] = tri[k][uvIdx]
+						}
+						if nIdx != -1 {
+							key[2] = tri[k][nIdx]
+						}
+						idx, seen := dedup[key]
+						if !seen {
+							mesh.Vertices = appendVertex(mesh.Vertices, triPos[k], triUV[k], uvIdx != -1, triNormal[k], options.NeedNormals)
+							idx = uint32(len(mesh.Vertices)/stride - 1)
+							dedup[key] = idx
+						}
+						mesh.Indices = append(mesh.Indices, idx)
+					} else {
+						mesh.Vertices = appendVertex(mesh.Vertices, triPos[k], triUV[k], uvIdx != -1, triNormal[k], options.NeedNormals)
+						if options.Indexed {
+							mesh.Indices = append(mesh.Indices, uint32(len(mesh.Vertices)/stride-1))
+						}
+					}
 				}
-				mesh := (*meshes)[meshID]
-				mesh.Vertices = append(mesh.Vertices, vertices...)
 			}
 			facesCounter++
 
+		// GROUPING (o) (g) (s)
+		// OBJECT NAME o (name)
+		case "o":
+			name := strings.TrimSpace(string(rest))
+			if name == "" {
+				name = "default"
+			}
+			currentObject = name
+
+		// GROUP NAME(S) g (name1) (name2) ...
+		// Multiple names on one "g" line are folded into a single compound
+		// group key (the names joined with a space) rather than giving the
+		// face real membership in each named group. A later "g a b" does
+		// NOT merge back into a group opened by an earlier "g a" -- they
+		// land in separate Mesh buckets, so callers should not assume
+		// obj.Groups["a"] exists just because "a" appeared in a "g" line.
+		case "g":
+			names := strings.Fields(string(rest))
+			if len(names) == 0 {
+				currentGroup = "default"
+			} else {
+				currentGroup = strings.Join(names, " ")
+			}
+
+		// SMOOTHING GROUP s off|<n>
+		case "s":
+			smoothing := strings.TrimSpace(string(rest))
+			if smoothing == "" {
+				smoothing = "off"
+			}
+			currentSmoothing = smoothing
+
 		// RENDER ATTRIBUTES (usemtl) (mtllib)
 		// USE MATERIAL usemtl (material_name)
 		case "usemtl":
 			// TODO: TURN ON MATERIALS
 
-			if len(parts) != 1 || parts[0] == "" {
+			names := strings.Fields(string(rest))
+			if len(names) != 1 || names[0] == "" {
 				currentMaterial = "default"
 			} else {
-				currentMaterial = parts[0]
+				currentMaterial = names[0]
 			}
 
 		// MATERIAL LIBRARY mtllib filepath1 filepath2 ....
 		case "mtllib":
-			// TODO: LOAD MATERIALS
-			for _, filePath := range parts {
-				err := LoadMtl(filePath, materials)
+			for _, libName := range strings.Fields(string(rest)) {
+				rc, err := resolver.Open(libName)
+				if err != nil {
+					return err
+				}
+				err = LoadMtlReader(rc, materials)
+				rc.Close()
 				if err != nil {
 					return err
 				}
 			}
 
 		default:
-			_, ok := _nsMap[key]
+			keyStr := string(key)
+			_, ok := _nsMap[keyStr]
 			if ok {
 				continue
 			}
-			fmt.Println(errorNotSupported("The given keyword is not supported", key))
-			_nsMap[key] = true
+			fmt.Println(errorNotSupported("The given keyword is not supported", keyStr))
+			_nsMap[keyStr] = true
 		}
 	}
 
-	err = scanner.Err()
+	err := scanner.Err()
 	if err != nil {
 		return err
 	}
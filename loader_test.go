@@ -0,0 +1,288 @@
+package objloader
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestParseFloatField(t *testing.T) {
+	cases := []struct {
+		in   string
+		want float32
+	}{
+		{"1.5 2.5", 1.5},
+		{"-3.25", -3.25},
+		{"1e3", 1000},
+		{"-1.5e-2", -0.015},
+		{"0", 0},
+		{"+2.5", 2.5},
+	}
+	for _, c := range cases {
+		got, n, err := parseFloatField([]byte(c.in))
+		if err != nil {
+			t.Fatalf("parseFloatField(%q): %v", c.in, err)
+		}
+		if n == 0 {
+			t.Fatalf("parseFloatField(%q): consumed 0 bytes", c.in)
+		}
+		if got != c.want {
+			t.Fatalf("parseFloatField(%q) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestParseFloatFieldRejectsGarbage(t *testing.T) {
+	if _, _, err := parseFloatField([]byte("abc")); err == nil {
+		t.Fatal("expected error for non-numeric field")
+	}
+}
+
+func TestParseFace(t *testing.T) {
+	cases := []struct {
+		in   string
+		want [3]int
+	}{
+		{"3", [3]int{3, 0, 0}},
+		{"3/4", [3]int{3, 4, 0}},
+		{"3/4/5", [3]int{3, 4, 5}},
+		{"3//5", [3]int{3, 0, 5}},
+		{"-1/-2/-3", [3]int{-1, -2, -3}},
+	}
+	for _, c := range cases {
+		got, n, err := parseFace([]byte(c.in))
+		if err != nil {
+			t.Fatalf("parseFace(%q): %v", c.in, err)
+		}
+		if n == 0 {
+			t.Fatalf("parseFace(%q): consumed 0 bytes", c.in)
+		}
+		if got != c.want {
+			t.Fatalf("parseFace(%q) = %v, want %v", c.in, got, c.want)
+		}
+	}
+}
+
+func TestFaceVtype(t *testing.T) {
+	cases := []struct {
+		face [3]int
+		want int
+	}{
+		{[3]int{1, 0, 0}, 0},
+		{[3]int{1, 2, 0}, 1},
+		{[3]int{1, 2, 3}, 2},
+		{[3]int{1, 0, 3}, 3},
+	}
+	for _, c := range cases {
+		if got := faceVtype(c.face); got != c.want {
+			t.Fatalf("faceVtype(%v) = %d, want %d", c.face, got, c.want)
+		}
+	}
+}
+
+func writeObj(t *testing.T, dir, body string) string {
+	t.Helper()
+	path := filepath.Join(dir, "a.obj")
+	if err := os.WriteFile(path, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func loadMesh(t *testing.T, path string, opts Options) *Mesh {
+	t.Helper()
+	scene := Scene{Objects: map[string]*Object{}}
+	materials := MaterialMap{}
+	if err := LoadObj(path, &scene, &materials, opts); err != nil {
+		t.Fatal(err)
+	}
+	obj := scene.Objects["default"]
+	if obj == nil {
+		t.Fatal("no default object")
+	}
+	for _, mesh := range obj.Groups {
+		return mesh
+	}
+	t.Fatal("no mesh produced")
+	return nil
+}
+
+func TestLoadObjNgonFanTriangulation(t *testing.T) {
+	dir := t.TempDir()
+	path := writeObj(t, dir, "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nv -1 1 0\nf 1 2 3 4 5\n")
+	mesh := loadMesh(t, path, Options{})
+	// A 5-gon fans into 3 triangles (N-2), 9 vertices of 3 floats each.
+	if want := 9 * 3; len(mesh.Vertices) != want {
+		t.Fatalf("got %d floats, want %d (3 triangles)", len(mesh.Vertices), want)
+	}
+}
+
+func TestLoadObjNegativeIndices(t *testing.T) {
+	dir := t.TempDir()
+	// Negative indices count back from the end of positions/normals/uvs.
+	path := writeObj(t, dir, "v 0 0 0\nv 1 0 0\nv 1 1 0\nf -3 -2 -1\n")
+	mesh := loadMesh(t, path, Options{})
+	want := []float32{0, 0, 0, 1, 0, 0, 1, 1, 0}
+	if len(mesh.Vertices) != len(want) {
+		t.Fatalf("got %v, want %v", mesh.Vertices, want)
+	}
+	for i := range want {
+		if mesh.Vertices[i] != want[i] {
+			t.Fatalf("got %v, want %v", mesh.Vertices, want)
+		}
+	}
+}
+
+func TestLoadObjIndexedDedup(t *testing.T) {
+	dir := t.TempDir()
+	// A quad fans into 2 triangles sharing an edge (vertices 1 and 3), so
+	// Indexed output should collapse 6 triangle corners down to 4 unique
+	// vertices and record the shared ones via repeated indices.
+	path := writeObj(t, dir, "v 0 0 0\nv 1 0 0\nv 1 1 0\nv 0 1 0\nf 1 2 3 4\n")
+	mesh := loadMesh(t, path, Options{Indexed: true})
+
+	if got, want := len(mesh.Vertices)/mesh.Stride, 4; got != want {
+		t.Fatalf("got %d unique vertices, want %d", got, want)
+	}
+	wantIndices := []uint32{0, 1, 2, 0, 2, 3}
+	if len(mesh.Indices) != len(wantIndices) {
+		t.Fatalf("Indices = %v, want %v", mesh.Indices, wantIndices)
+	}
+	for i := range wantIndices {
+		if mesh.Indices[i] != wantIndices[i] {
+			t.Fatalf("Indices = %v, want %v", mesh.Indices, wantIndices)
+		}
+	}
+}
+
+func TestLoadObjMtllibResolvesRelativeToObjDir(t *testing.T) {
+	dir := t.TempDir()
+	sub := filepath.Join(dir, "sub")
+	if err := os.Mkdir(sub, 0755); err != nil {
+		t.Fatal(err)
+	}
+	// mtllib names are relative to the OBJ file's own directory, not the
+	// process's current working directory -- writing the .mtl alongside
+	// the .obj in a directory the test never chdirs into is what proves
+	// that, rather than the two happening to share a cwd.
+	mtlPath := filepath.Join(sub, "material.mtl")
+	if err := os.WriteFile(mtlPath, []byte("newmtl red\nKd 1 0 0\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	objPath := filepath.Join(sub, "a.obj")
+	body := "mtllib material.mtl\nv 0 0 0\nv 1 0 0\nv 1 1 0\nusemtl red\nf 1 2 3\n"
+	if err := os.WriteFile(objPath, []byte(body), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	scene := Scene{Objects: map[string]*Object{}}
+	materials := MaterialMap{}
+	if err := LoadObj(objPath, &scene, &materials, Options{}); err != nil {
+		t.Fatal(err)
+	}
+	red := materials["red"]
+	if red == nil {
+		t.Fatal("mtllib was not resolved relative to the OBJ's directory")
+	}
+	if red.Kd != (Vec3{1, 0, 0}) {
+		t.Fatalf("Kd = %v, want {1 0 0}", red.Kd)
+	}
+}
+
+func TestParseTextureMapOptions(t *testing.T) {
+	materials := MaterialMap{}
+	body := "newmtl red\nmap_Kd -o 0.25 0 0 -s 1 2 1 diffuse.png\n"
+	r := strings.NewReader(body)
+	if err := LoadMtlReader(r, &materials); err != nil {
+		t.Fatal(err)
+	}
+	red := materials["red"]
+	if red == nil {
+		t.Fatal("material \"red\" was not parsed")
+	}
+	if red.MapKd != "diffuse.png" {
+		t.Fatalf("MapKd = %q, want %q", red.MapKd, "diffuse.png")
+	}
+	opts, ok := red.MapOptions["map_Kd"]
+	if !ok {
+		t.Fatal("MapOptions[\"map_Kd\"] missing")
+	}
+	if opts.Offset != (Vec3{0.25, 0, 0}) {
+		t.Fatalf("Offset = %v, want {0.25 0 0}", opts.Offset)
+	}
+	if opts.Scale != (Vec3{1, 2, 1}) {
+		t.Fatalf("Scale = %v, want {1 2 1}", opts.Scale)
+	}
+}
+
+func TestLoadObjMultiObjectMultiGroup(t *testing.T) {
+	dir := t.TempDir()
+	body := "o cubeObj\n" +
+		"v 0 0 0\nv 1 0 0\nv 1 1 0\n" +
+		"g top\n" +
+		"f 1 2 3\n" +
+		"o sphereObj\n" +
+		"v 0 0 1\nv 1 0 1\nv 1 1 1\n" +
+		"g bottom\n" +
+		"s 2\n" +
+		"f 1 2 3\n"
+	path := writeObj(t, dir, body)
+
+	scene := Scene{Objects: map[string]*Object{}}
+	materials := MaterialMap{}
+	if err := LoadObj(path, &scene, &materials, Options{}); err != nil {
+		t.Fatal(err)
+	}
+
+	cube := scene.Objects["cubeObj"]
+	if cube == nil {
+		t.Fatal("o cubeObj did not produce its own Scene.Objects entry")
+	}
+	cubeMesh := cube.Groups["grouptop-vtype0-materialdefault"]
+	if cubeMesh == nil {
+		t.Fatalf("cubeObj.Groups = %v, missing the \"top\" group bucket", cube.Groups)
+	}
+	if cubeMesh.Smoothing != "off" {
+		t.Fatalf("cubeObj mesh Smoothing = %q, want %q", cubeMesh.Smoothing, "off")
+	}
+
+	sphere := scene.Objects["sphereObj"]
+	if sphere == nil {
+		t.Fatal("o sphereObj did not produce its own Scene.Objects entry")
+	}
+	sphereMesh := sphere.Groups["groupbottom-vtype0-materialdefault"]
+	if sphereMesh == nil {
+		t.Fatalf("sphereObj.Groups = %v, missing the \"bottom\" group bucket", sphere.Groups)
+	}
+	if sphereMesh.Smoothing != "2" {
+		t.Fatalf("sphereObj mesh Smoothing = %q, want %q", sphereMesh.Smoothing, "2")
+	}
+}
+
+func TestLoadObjFaceFormats(t *testing.T) {
+	dir := t.TempDir()
+	body := "v 0 0 0\nv 1 0 0\nv 1 1 0\n" +
+		"vt 0 0\nvt 1 0\nvt 1 1\n" +
+		"vn 0 0 1\nvn 0 0 1\nvn 0 0 1\n"
+
+	cases := []struct {
+		name  string
+		face  string
+		vtype int
+	}{
+		{"v", "f 1 2 3", 0},
+		{"v-vt", "f 1/1 2/2 3/3", 1},
+		{"v-vt-vn", "f 1/1/1 2/2/2 3/3/3", 2},
+		{"v--vn", "f 1//1 2//2 3//3", 3},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			path := writeObj(t, dir, body+c.face+"\n")
+			mesh := loadMesh(t, path, Options{})
+			if mesh.Vtype != c.vtype {
+				t.Fatalf("%s: got vtype %d, want %d", c.name, mesh.Vtype, c.vtype)
+			}
+		})
+	}
+}